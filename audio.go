@@ -0,0 +1,160 @@
+//go:build portaudio
+
+// This file cgo-links against the native PortAudio library, which isn't
+// available on every machine (including plain CI images), so it's built
+// only with `go build -tags portaudio`.
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// PlayOptions configures PlayAudio.
+type PlayOptions struct {
+	// SampleRate is the audio output sample rate in Hz. Defaults to 44100
+	// when zero.
+	SampleRate int
+
+	// Samples maps instrument names to mono PCM buffers (-1.0..1.0) played
+	// whenever that instrument triggers on a step. Instruments without an
+	// entry fall back to a built-in synthesized hit.
+	Samples map[string][]float32
+
+	// Loop repeats the pattern indefinitely instead of stopping after one
+	// pass.
+	Loop bool
+}
+
+const defaultSampleRate = 44100
+
+// PlayAudio plays the pattern last passed to Render through the system's
+// default audio device using PortAudio. Instead of sleeping, it advances a
+// sample-counter-based clock of 1/32-note ticks
+// (samplesPerTick = SampleRate * 60 / (bpm * 8); a 1/16 step spans two
+// ticks), so playback stays jitter-free regardless of scheduler load.
+func (d *drumMachine) PlayAudio(bpm int32, opts PlayOptions) error {
+	sampleRate := opts.SampleRate
+	if sampleRate == 0 {
+		sampleRate = defaultSampleRate
+	}
+
+	if err := portaudio.Initialize(); err != nil {
+		return err
+	}
+	defer portaudio.Terminate()
+
+	// samplesPerTick is the sample count for one 1/32-note tick, the same
+	// finest grid used to line up polymetric tracks in Render/RenderMIDI.
+	samplesPerTick := sampleRate * 60 / (int(bpm) * 8)
+	numTicks := patternTicks(d.pattern)
+
+	var tick int
+	var sampleInTick int
+	var finished bool
+	done := make(chan struct{})
+	var closeDone sync.Once
+	active := make(map[int]int) // row -> playhead into its sample/synth buffer
+
+	triggerTick := func() {
+		for row := range d.pattern.instrumentNames {
+			cell := ticksPerCell(d.pattern.resolution[row])
+			tickInTrack := tick % trackTicks(d.pattern.stepsPerTrack[row], d.pattern.resolution[row])
+			if tickInTrack%cell != 0 {
+				continue
+			}
+			step := (tickInTrack / cell) % len(d.pattern.track[row])
+			if d.pattern.track[row][step] {
+				active[row] = 0
+			}
+		}
+	}
+	triggerTick()
+
+	callback := func(out [][]float32) {
+		for i := range out[0] {
+			if finished {
+				out[0][i] = 0
+				out[1][i] = 0
+				continue
+			}
+
+			var mixed float32
+			for row, playhead := range active {
+				name := d.pattern.instrumentNames[row]
+				var s float32
+				if buf, ok := opts.Samples[name]; ok {
+					if playhead < len(buf) {
+						s = buf[playhead]
+					} else {
+						delete(active, row)
+						continue
+					}
+				} else {
+					s = synthesize(name, playhead, sampleRate)
+					if playhead > sampleRate/4 {
+						delete(active, row)
+						continue
+					}
+				}
+				mixed += s
+				active[row] = playhead + 1
+			}
+
+			out[0][i] = mixed
+			out[1][i] = mixed
+
+			sampleInTick++
+			if sampleInTick >= samplesPerTick {
+				sampleInTick = 0
+				tick++
+				if tick >= numTicks {
+					if !opts.Loop {
+						finished = true
+						closeDone.Do(func() { close(done) })
+						continue
+					}
+					tick = 0
+				}
+				triggerTick()
+			}
+		}
+	}
+
+	stream, err := portaudio.OpenDefaultStream(0, 2, float64(sampleRate), samplesPerTick, callback)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	if err := stream.Start(); err != nil {
+		return err
+	}
+
+	if opts.Loop {
+		select {} // loop forever; caller kills the process to stop playback
+	}
+
+	<-done
+	return stream.Stop()
+}
+
+// synthesize produces a short default hit for instruments with no supplied
+// sample: a pitched sine burst for low, tonal instruments (kick, toms) and
+// an enveloped white-noise burst for everything else (hats, snare, clap).
+// The noise is not filtered.
+func synthesize(instrument string, sampleIndex int, sampleRate int) float32 {
+	t := float64(sampleIndex) / float64(sampleRate)
+	envelope := math.Exp(-t * 20)
+
+	switch instrument {
+	case "kick", "tom", "floor-tom", "low-tom", "mid-tom", "high-tom":
+		freq := 80.0
+		return float32(math.Sin(2*math.Pi*freq*t) * envelope)
+	default:
+		return float32((rand.Float64()*2 - 1) * envelope)
+	}
+}
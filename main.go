@@ -11,6 +11,22 @@ import (
 type Pattern struct {
 	instrumentNames []string
 	track           [][]bool
+
+	// stepsPerTrack holds the number of steps in each row of track, in the
+	// same order as instrumentNames. A pattern is polymetric when these
+	// differ between tracks: a shorter track loops against a longer one.
+	stepsPerTrack []int
+
+	// resolution holds the note value (8, 16 or 32, meaning 1/8, 1/16 or
+	// 1/32) that each track's steps are spaced at. Defaults to 16.
+	resolution []int
+
+	// trackIDs holds the hardware track ID for each row in track, in the
+	// same order as instrumentNames. It is only populated when the Pattern
+	// came from (or is destined for) the binary .splice format.
+	trackIDs []byte
+	version  string
+	tempo    float32
 }
 
 type Sequencer interface {
@@ -44,31 +60,51 @@ type Sequencer interface {
 }
 
 type drumMachine struct {
-	render string
+	render  string
+	pattern Pattern
+
+	// instrumentMap overrides the default instrument name to General MIDI
+	// drum note mapping used by RenderMIDI. Nil means use the defaults.
+	instrumentMap map[string]uint8
+
+	// ppqn overrides the pulses-per-quarter-note resolution used by
+	// RenderMIDI. Zero means use defaultPPQN.
+	ppqn uint16
 }
 
 func NewDrumMachine() Sequencer {
 	return &drumMachine{}
 }
 
-//instrumentNames := []string{"hi-hat", "snare", "kick"}
-//track := [][]bool{
-//	{true, false, true, false, true, false, true, false, true, false, true, false, true, false, true, false},
-//	{false, false, false, false, true, false, false, false, false, false, false, false, true, false, false, false},
-//	{true, false, false, false, false, false, false, false, true, false, false, false, false, false, false, false},
-//}
+// instrumentNames := []string{"hi-hat", "snare", "kick"}
+//
+//	track := [][]bool{
+//		{true, false, true, false, true, false, true, false, true, false, true, false, true, false, true, false},
+//		{false, false, false, false, true, false, false, false, false, false, false, false, true, false, false, false},
+//		{true, false, false, false, false, false, false, false, true, false, false, false, false, false, false, false},
+//	}
 func (d *drumMachine) Parse(pattern string) (Pattern, error) {
 	var instrumentNames []string
 	track := make([][]bool, 0)
+	var stepsPerTrack []int
+	var resolution []int
+	headerResolution := defaultResolution
 	rows := 0
 	scanner := bufio.NewScanner(strings.NewReader(pattern)) // reading line by line
 	for scanner.Scan() {
-		before, after, found := strings.Cut(scanner.Text(), "|") // get instrument name
+		line := scanner.Text()
+
+		if res, ok := parseResolutionHeader(strings.TrimSpace(line)); ok {
+			headerResolution = res // "@resolution 1/N" sets the default for tracks that follow
+			continue
+		}
+
+		before, after, found := splitInstrumentLine(line) // get instrument name
 		if !found {
 			return Pattern{}, errors.New("incorrect format")
 		}
-		instrumentName := strings.TrimSpace(before)    // remove white spaces from instrument name
-		sequence := strings.ReplaceAll(after, "|", "") // remove all | from sequence
+		instrumentName, steps := splitTrackSteps(before) // strip an optional "(N)" step-count override
+		sequence := strings.ReplaceAll(after, "|", "")   // remove all | from sequence
 
 		if contains(instrumentNames, instrumentName) { // not the fastest way, but we should check the duplicated instrument names
 			return Pattern{}, errors.New("duplicated instrument name")
@@ -78,12 +114,27 @@ func (d *drumMachine) Parse(pattern string) (Pattern, error) {
 		for _, v := range sequence { // read sequence and saving the booleans in a list
 			row = append(row, getBooleanPlay(string(v)))
 		}
+		if len(row) == 0 {
+			return Pattern{}, errors.New("track has no steps")
+		}
+		if steps == 0 {
+			steps = len(row) // no override: the track is exactly as long as its sequence
+		} else if steps != len(row) {
+			return Pattern{}, errors.New("track step-count override does not match its sequence length")
+		}
 
 		track = append(track, row) // saving the track row on the 2d slice
+		stepsPerTrack = append(stepsPerTrack, steps)
+		resolution = append(resolution, headerResolution)
 
 		rows++ // next row
 	}
-	return Pattern{instrumentNames: instrumentNames, track: track}, nil
+	return Pattern{
+		instrumentNames: instrumentNames,
+		track:           track,
+		stepsPerTrack:   stepsPerTrack,
+		resolution:      resolution,
+	}, nil
 }
 
 func contains(s []string, e string) bool {
@@ -102,7 +153,7 @@ func getBooleanPlay(beat string) bool {
 	return false
 }
 
-//|hi-hat,kick|-|hi-hat|-|hi-hat,snare|-|hi-hat|-|hi-hat,kick|-|hi-hat|-|hi-hat,snare|-|hi-hat|-|
+// |hi-hat,kick|-|hi-hat|-|hi-hat,snare|-|hi-hat|-|hi-hat,kick|-|hi-hat|-|hi-hat,snare|-|hi-hat|-|
 func (d *drumMachine) Render(pattern Pattern) (string, error) {
 	//fmt.Println(pattern.track[0][0])
 	//fmt.Println(pattern.track[1][0])
@@ -115,9 +166,20 @@ func (d *drumMachine) Render(pattern Pattern) (string, error) {
 	var play string
 	divisor := "|"
 
-	for i := 0; i < len(pattern.track[0]); i++ { // iterate over column
+	// Walk the pattern tick by tick (at the finest resolution actually used)
+	// rather than step by step, so tracks with different lengths and
+	// resolutions (polymeter) line up correctly: a shorter/coarser track
+	// repeats until the longer/finer ones complete a full cycle.
+	granularity := renderGranularity(pattern)
+	for tick := 0; tick < patternTicks(pattern); tick += granularity { // iterate over column
 		for j := 0; j < len(pattern.track); j++ { // iterate over rows
-			if pattern.track[j][i] {
+			cell := ticksPerCell(pattern.resolution[j])
+			tickInTrack := tick % trackTicks(pattern.stepsPerTrack[j], pattern.resolution[j])
+			if tickInTrack%cell != 0 {
+				continue // not a step boundary for this track yet
+			}
+			step := (tickInTrack / cell) % len(pattern.track[j])
+			if pattern.track[j][step] {
 				columnPlay = append(columnPlay, pattern.instrumentNames[j])
 			}
 		}
@@ -134,7 +196,8 @@ func (d *drumMachine) Render(pattern Pattern) (string, error) {
 		play = divisor + play
 	}
 
-	d.render = play // add new render on drum machine
+	d.render = play     // add new render on drum machine
+	d.pattern = pattern // keep the structured pattern around for PlayAudio
 
 	return play, nil
 }
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+)
+
+// defaultPPQN is the pulses (ticks) per quarter note used when writing MIDI
+// files, matching the common default of most DAWs and drum machines.
+const defaultPPQN = 96
+
+// defaultInstrumentMap maps instrument names used in a Pattern to their
+// General MIDI drum note on channel 10. Names not present here fall back to
+// an acoustic snare (38) so a pattern still produces audible output.
+var defaultInstrumentMap = map[string]uint8{
+	"kick":    36, // C1
+	"snare":   38, // D1
+	"hi-hat":  42, // F#1
+	"clap":    39, // D#1
+	"cowbell": 56, // G#2
+}
+
+// SetInstrumentMap overrides the instrument name to General MIDI drum note
+// mapping used by RenderMIDI. Names not present in m fall back to the
+// built-in defaults.
+func (d *drumMachine) SetInstrumentMap(m map[string]uint8) {
+	d.instrumentMap = m
+}
+
+// SetPPQN overrides the pulses-per-quarter-note resolution used by
+// RenderMIDI. Zero resets it to defaultPPQN.
+func (d *drumMachine) SetPPQN(ppqn uint16) {
+	d.ppqn = ppqn
+}
+
+func (d *drumMachine) ppqnOrDefault() uint16 {
+	if d.ppqn == 0 {
+		return defaultPPQN
+	}
+	return d.ppqn
+}
+
+func (d *drumMachine) noteFor(instrument string) uint8 {
+	if d.instrumentMap != nil {
+		if note, ok := d.instrumentMap[instrument]; ok {
+			return note
+		}
+	}
+	if note, ok := defaultInstrumentMap[instrument]; ok {
+		return note
+	}
+	return 38
+}
+
+// RenderMIDI writes p as a standard MIDI file (SMF type 0) to w, mapping
+// each instrument to a General MIDI drum note on channel 10 (index 9).
+// Every track's steps are placed at that track's own resolution (a 1/16
+// track at 16th-note intervals) over one full polymetric cycle of p, using
+// the drumMachine's PPQN (configurable via SetPPQN, default 96), with tempo
+// derived from bpm.
+func (d *drumMachine) RenderMIDI(p Pattern, bpm int32, w io.Writer) error {
+	if bpm <= 0 {
+		return errors.New("midi: bpm must be positive")
+	}
+
+	ppqn := d.ppqnOrDefault()
+
+	var track bytes.Buffer
+
+	writeMetaTempo(&track, bpm)
+
+	// ticksPer32nd converts a pattern tick (1/32-note grid, shared with
+	// Render's polymeter handling) into MIDI ticks at ppqn.
+	ticksPer32nd := uint32(ppqn / 8)
+	const channel = 9
+
+	type event struct {
+		tick uint32
+		note uint8
+		on   bool
+	}
+	var events []event
+	granularity := renderGranularity(p)
+	for tick := 0; tick < patternTicks(p); tick += granularity {
+		midiTick := uint32(tick) * ticksPer32nd
+		for row, name := range p.instrumentNames {
+			cell := ticksPerCell(p.resolution[row])
+			tickInTrack := tick % trackTicks(p.stepsPerTrack[row], p.resolution[row])
+			if tickInTrack%cell != 0 {
+				continue
+			}
+			step := (tickInTrack / cell) % len(p.track[row])
+			if !p.track[row][step] {
+				continue
+			}
+			note := d.noteFor(name)
+			noteLength := uint32(cell) * ticksPer32nd
+			events = append(events, event{tick: midiTick, note: note, on: true})
+			events = append(events, event{tick: midiTick + noteLength - 1, note: note, on: false})
+		}
+	}
+
+	// Events are appended per instrument within a tick, so two simultaneous
+	// hits can interleave an earlier note's off after a later note's on;
+	// sort chronologically before delta-encoding or deltas go negative.
+	sort.SliceStable(events, func(i, j int) bool { return events[i].tick < events[j].tick })
+
+	var last uint32
+	for _, e := range events {
+		writeVarLen(&track, e.tick-last)
+		last = e.tick
+		status := byte(0x90 | channel)
+		velocity := byte(0x64)
+		if !e.on {
+			velocity = 0
+		}
+		track.WriteByte(status)
+		track.WriteByte(e.note)
+		track.WriteByte(velocity)
+	}
+
+	writeVarLen(&track, 0)
+	track.Write([]byte{0xFF, 0x2F, 0x00}) // end of track
+
+	if err := writeMIDIHeader(w, 1, ppqn); err != nil {
+		return err
+	}
+	return writeMIDITrack(w, track.Bytes())
+}
+
+func writeMIDIHeader(w io.Writer, numTracks uint16, ppqn uint16) error {
+	if _, err := w.Write([]byte("MThd")); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(6)); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint16(0)); err != nil { // format 0
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, numTracks); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, ppqn)
+}
+
+func writeMIDITrack(w io.Writer, data []byte) error {
+	if _, err := w.Write([]byte("MTrk")); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(data))); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// writeMetaTempo emits a tempo meta event (microseconds per quarter note
+// derived from bpm) at the start of the track.
+func writeMetaTempo(track *bytes.Buffer, bpm int32) {
+	writeVarLen(track, 0)
+	microsPerQuarter := uint32(60000000 / bpm)
+	track.Write([]byte{0xFF, 0x51, 0x03})
+	track.WriteByte(byte(microsPerQuarter >> 16))
+	track.WriteByte(byte(microsPerQuarter >> 8))
+	track.WriteByte(byte(microsPerQuarter))
+}
+
+// writeVarLen encodes v as a MIDI variable-length quantity.
+func writeVarLen(buf *bytes.Buffer, v uint32) {
+	var stack [4]byte
+	n := 0
+	stack[n] = byte(v & 0x7F)
+	n++
+	v >>= 7
+	for v > 0 {
+		stack[n] = byte(v&0x7F) | 0x80
+		n++
+		v >>= 7
+	}
+	for i := n - 1; i >= 0; i-- {
+		buf.WriteByte(stack[i])
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// RenderPNGOptions configures RenderPNG.
+type RenderPNGOptions struct {
+	// CellSize is the width and height, in pixels, of a single step cell.
+	// Defaults to 24 when zero.
+	CellSize int
+
+	// LabelWidth is the width, in pixels, reserved on the left for
+	// instrument names. Defaults to 80 when zero.
+	LabelWidth int
+
+	// Background, GridColor, BeatColor and ActiveColor style the grid.
+	// Any left as nil fall back to sensible defaults.
+	Background  color.Color
+	GridColor   color.Color
+	BeatColor   color.Color
+	ActiveColor color.Color
+
+	// Font is used to draw instrument names. Defaults to basicfont.Face7x13.
+	Font font.Face
+}
+
+const (
+	defaultCellSize   = 24
+	defaultLabelWidth = 80
+)
+
+// RenderPNG draws p as a grid image, one row per instrument, and writes it
+// to w as a PNG. A heavier vertical rule marks each beat (quarter-note)
+// boundary, which groups 1/16 columns in fours, and active steps are drawn
+// as filled cells.
+func RenderPNG(p Pattern, w io.Writer, opts RenderPNGOptions) error {
+	cellSize := opts.CellSize
+	if cellSize == 0 {
+		cellSize = defaultCellSize
+	}
+	labelWidth := opts.LabelWidth
+	if labelWidth == 0 {
+		labelWidth = defaultLabelWidth
+	}
+
+	background := opts.Background
+	if background == nil {
+		background = color.White
+	}
+	gridColor := opts.GridColor
+	if gridColor == nil {
+		gridColor = color.Gray{Y: 200}
+	}
+	beatColor := opts.BeatColor
+	if beatColor == nil {
+		beatColor = color.Gray{Y: 100}
+	}
+	activeColor := opts.ActiveColor
+	if activeColor == nil {
+		activeColor = color.Black
+	}
+	face := opts.Font
+	if face == nil {
+		face = basicfont.Face7x13
+	}
+
+	granularity := renderGranularity(p)
+	cols := patternTicks(p) / granularity
+	if cols == 0 {
+		cols = 1
+	}
+	rows := len(p.instrumentNames)
+
+	width := labelWidth + cols*cellSize
+	height := rows * cellSize
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	draw(img, image.Rect(0, 0, width, height), background)
+
+	for row, name := range p.instrumentNames {
+		y := row * cellSize
+		drawLabel(img, face, name, 4, y+cellSize/2+4)
+
+		for col := 0; col < cols; col++ {
+			x := labelWidth + col*cellSize
+			cell := image.Rect(x, y, x+cellSize, y+cellSize)
+
+			if stepActiveAtColumn(p, row, col, granularity) {
+				draw(img, cell, activeColor)
+			}
+
+			drawRect(img, cell, gridColor)
+			if (col*granularity)%8 == 0 { // 8 ticks of 1/32 make one beat
+				draw(img, image.Rect(x, y, x+2, y+cellSize), beatColor)
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// stepActiveAtColumn reports whether instrument row is active at rendered
+// column col, where a column is one renderGranularity-sized tick in the
+// pattern's full polymetric cycle.
+func stepActiveAtColumn(p Pattern, row, col, granularity int) bool {
+	cell := ticksPerCell(p.resolution[row])
+	tick := col * granularity
+	tickInTrack := tick % trackTicks(p.stepsPerTrack[row], p.resolution[row])
+	if tickInTrack%cell != 0 {
+		return false
+	}
+	step := (tickInTrack / cell) % len(p.track[row])
+	return p.track[row][step]
+}
+
+func draw(img *image.RGBA, r image.Rectangle, c color.Color) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+func drawRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	for x := r.Min.X; x < r.Max.X; x++ {
+		img.Set(x, r.Min.Y, c)
+		img.Set(x, r.Max.Y-1, c)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		img.Set(r.Min.X, y, c)
+		img.Set(r.Max.X-1, y, c)
+	}
+}
+
+func drawLabel(img *image.RGBA, face font.Face, label string, x, y int) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(color.Black),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(label)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultResolution is the step resolution (1/16 notes) assumed for a track
+// when neither a "@resolution" header nor a per-track override is present.
+const defaultResolution = 16
+
+// splitInstrumentLine splits a track line into its instrument name and the
+// "|...|" step groups that follow it, the name/sequence split shared by
+// Parse and ParseSong.
+func splitInstrumentLine(line string) (name string, groups string, ok bool) {
+	idx := strings.Index(line, "|")
+	if idx < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(line[:idx]), line[idx:], true
+}
+
+// trackPrefixPattern matches an optional "(N)" step-count override right
+// after the instrument name, e.g. "shaker (12) |x-x-x-x-x-x-|".
+var trackPrefixPattern = regexp.MustCompile(`^(.*?)\s*\((\d+)\)$`)
+
+// resolutionHeaderPattern matches a "@resolution 1/16" header line, which
+// sets the default step resolution for tracks that follow it.
+var resolutionHeaderPattern = regexp.MustCompile(`^@resolution\s+1/(\d+)$`)
+
+// parseResolutionHeader reports whether line is a "@resolution 1/N" header
+// with N one of 8, 16 or 32 and, if so, returns N.
+func parseResolutionHeader(line string) (int, bool) {
+	m := resolutionHeaderPattern.FindStringSubmatch(line)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || (n != 8 && n != 16 && n != 32) {
+		return 0, false
+	}
+	return n, true
+}
+
+// splitTrackSteps pulls an optional "(N)" step-count override off the end
+// of an instrument name, returning the bare name and the override (0 when
+// absent).
+func splitTrackSteps(name string) (string, int) {
+	m := trackPrefixPattern.FindStringSubmatch(name)
+	if m == nil {
+		return name, 0
+	}
+	n, err := strconv.Atoi(m[2])
+	if err != nil {
+		return name, 0
+	}
+	return m[1], n
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func lcm(a, b int) int {
+	return a / gcd(a, b) * b
+}
+
+// ticksPerCell returns how many 1/32-note ticks a single step occupies at
+// the given resolution (8, 16 or 32).
+func ticksPerCell(resolution int) int {
+	return 32 / resolution
+}
+
+// trackTicks returns the total number of 1/32-note ticks a single loop of
+// a track spans, given its step count and resolution.
+func trackTicks(steps, resolution int) int {
+	return steps * ticksPerCell(resolution)
+}
+
+// patternTicks returns the number of 1/32-note ticks in one full cycle of
+// p, i.e. the LCM of every track's trackTicks so each track repeats a
+// whole number of times.
+func patternTicks(p Pattern) int {
+	total := 1
+	for i := range p.track {
+		total = lcm(total, trackTicks(p.stepsPerTrack[i], p.resolution[i]))
+	}
+	return total
+}
+
+// renderGranularity returns the tick step a column walk should advance by:
+// the finest resolution's cell size across every track in p. Without this,
+// walking tick by tick at the absolute 1/32 grid emits a column for every
+// tick even when no track is that fine, doubling (or worse) the idle
+// columns in the common case where every track shares one resolution.
+func renderGranularity(p Pattern) int {
+	if len(p.track) == 0 {
+		return 1
+	}
+	step := ticksPerCell(p.resolution[0])
+	for _, res := range p.resolution[1:] {
+		step = gcd(step, ticksPerCell(res))
+	}
+	return step
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Section is a single measure-group within a Song: a Pattern played Repeat
+// times before moving on to the next section.
+type Section struct {
+	Pattern Pattern
+	Repeat  int
+	Name    string
+}
+
+// Song is an ordered arrangement of Sections, lifting the module from a
+// single-bar demo to something that can express a real arrangement.
+type Song struct {
+	Sections []Section
+}
+
+// ParseSong parses a multi-measure text arrangement into a Song. Each
+// instrument line may list several measures of steps, space-separated,
+// e.g.:
+//
+//	kick |x---|x---|x---|x---| |x-x-|x-x-|x-x-|x-x-|
+//	snare |----|x---|----|x---| |----|x---|----|x---|
+//
+// Every instrument must list the same number of measures; those measures
+// are spliced together, in order, into one Section per measure with a
+// default Repeat of 1. A "@resolution 1/N" header line, if present, applies
+// to every instrument line that follows it, exactly as it does for a single
+// Pattern parsed by drumMachine.Parse.
+func ParseSong(song string) (Song, error) {
+	type instrumentGroups struct {
+		name       string
+		groups     []string
+		resolution int // resolution in effect when this instrument's line was read
+	}
+	var instruments []instrumentGroups
+	numMeasures := -1
+	currentResolution := defaultResolution
+
+	scanner := bufio.NewScanner(strings.NewReader(song))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if res, ok := parseResolutionHeader(trimmed); ok {
+			currentResolution = res
+			continue
+		}
+
+		name, rest, found := splitInstrumentLine(line)
+		if !found {
+			return Song{}, errors.New("incorrect format")
+		}
+		groups := strings.Fields(rest)
+		if numMeasures == -1 {
+			numMeasures = len(groups)
+		} else if len(groups) != numMeasures {
+			return Song{}, errors.New("every instrument must list the same number of measures")
+		}
+
+		instruments = append(instruments, instrumentGroups{name: name, groups: groups, resolution: currentResolution})
+	}
+	if numMeasures <= 0 {
+		return Song{}, errors.New("incorrect format")
+	}
+
+	dm := NewDrumMachine()
+	var sections []Section
+	for m := 0; m < numMeasures; m++ {
+		var measureLines []string
+		activeResolution := defaultResolution
+		for _, instrument := range instruments {
+			if instrument.resolution != activeResolution {
+				activeResolution = instrument.resolution
+				measureLines = append(measureLines, fmt.Sprintf("@resolution 1/%d", activeResolution))
+			}
+			measureLines = append(measureLines, instrument.name+" "+instrument.groups[m])
+		}
+
+		p, err := dm.Parse(strings.Join(measureLines, "\n"))
+		if err != nil {
+			return Song{}, err
+		}
+
+		sections = append(sections, Section{
+			Pattern: p,
+			Repeat:  1,
+			Name:    fmt.Sprintf("section %d", m+1),
+		})
+	}
+
+	return Song{Sections: sections}, nil
+}
+
+// RenderSong returns the concatenated Render output of every section in
+// song, in order, with each section repeated Section.Repeat times.
+func RenderSong(song Song) (string, error) {
+	dm := NewDrumMachine()
+	var b strings.Builder
+	for _, section := range song.Sections {
+		rendered, err := dm.Render(section.Pattern)
+		if err != nil {
+			return "", err
+		}
+		for i := 0; i < section.Repeat; i++ {
+			b.WriteString(rendered)
+		}
+	}
+	return b.String(), nil
+}
+
+// PlaySong plays every section of song in sequence at the given tempo,
+// honoring each section's Repeat count.
+func PlaySong(song Song, bpm int32) error {
+	dm := NewDrumMachine()
+	for _, section := range song.Sections {
+		for i := 0; i < section.Repeat; i++ {
+			if _, err := dm.Render(section.Pattern); err != nil {
+				return err
+			}
+			if err := dm.Play(bpm); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// spliceMagic is the fixed 6-byte header that identifies a .splice file.
+var spliceMagic = []byte("SPLICE")
+
+// splice step data is always a single measure of 16th notes.
+const spliceSteps = 16
+
+// DecodeSplice reads the binary .splice format used by the Go Challenge #1
+// dataset and returns the Pattern it describes.
+//
+// The layout is: a 6-byte "SPLICE" magic, a big-endian int64 giving the
+// number of bytes remaining to read (some files have garbage after this
+// length and it must be ignored), a null-padded 32-byte hardware version
+// string, a little-endian float32 tempo, and then tracks until the byte
+// budget is exhausted. Each track is a 1-byte ID, a big-endian int32 name
+// length, the ASCII name, and exactly 16 bytes of step data (0x00 silent,
+// 0x01 play).
+func DecodeSplice(r io.Reader) (Pattern, error) {
+	magic := make([]byte, len(spliceMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return Pattern{}, errors.New("splice: could not read magic")
+	}
+	if !bytes.Equal(magic, spliceMagic) {
+		return Pattern{}, errors.New("splice: bad magic")
+	}
+
+	var size int64
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return Pattern{}, errors.New("splice: could not read size")
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return Pattern{}, errors.New("splice: truncated body")
+	}
+	br := bytes.NewReader(body)
+
+	versionRaw := make([]byte, 32)
+	if _, err := io.ReadFull(br, versionRaw); err != nil {
+		return Pattern{}, errors.New("splice: could not read version")
+	}
+	version := string(bytes.TrimRight(versionRaw, "\x00"))
+
+	var tempo float32
+	if err := binary.Read(br, binary.LittleEndian, &tempo); err != nil {
+		return Pattern{}, errors.New("splice: could not read tempo")
+	}
+
+	p := Pattern{version: version, tempo: tempo}
+	for br.Len() > 0 {
+		id, err := br.ReadByte()
+		if err != nil {
+			return Pattern{}, errors.New("splice: could not read track id")
+		}
+
+		var nameLen int32
+		if err := binary.Read(br, binary.BigEndian, &nameLen); err != nil {
+			return Pattern{}, errors.New("splice: could not read name length")
+		}
+		if nameLen < 0 || int(nameLen) > br.Len() {
+			return Pattern{}, errors.New("splice: invalid name length")
+		}
+
+		name := make([]byte, nameLen)
+		if _, err := io.ReadFull(br, name); err != nil {
+			return Pattern{}, errors.New("splice: could not read name")
+		}
+
+		steps := make([]byte, spliceSteps)
+		if _, err := io.ReadFull(br, steps); err != nil {
+			return Pattern{}, errors.New("splice: could not read steps")
+		}
+
+		row := make([]bool, spliceSteps)
+		for i, s := range steps {
+			row[i] = s == 0x01
+		}
+
+		p.instrumentNames = append(p.instrumentNames, string(name))
+		p.track = append(p.track, row)
+		p.trackIDs = append(p.trackIDs, id)
+		p.stepsPerTrack = append(p.stepsPerTrack, spliceSteps)
+		p.resolution = append(p.resolution, defaultResolution)
+	}
+
+	return p, nil
+}
+
+// EncodeSplice writes p to w in the binary .splice format understood by
+// DecodeSplice. Encoding a Pattern produced by DecodeSplice reproduces the
+// decoded file byte for byte up to the length in its header; any bytes past
+// that length, which DecodeSplice ignores, are not written.
+func EncodeSplice(p Pattern, w io.Writer) error {
+	var body bytes.Buffer
+
+	version := make([]byte, 32)
+	copy(version, p.version)
+	if _, err := body.Write(version); err != nil {
+		return err
+	}
+
+	if err := binary.Write(&body, binary.LittleEndian, p.tempo); err != nil {
+		return err
+	}
+
+	for i, row := range p.track {
+		var id byte
+		if i < len(p.trackIDs) {
+			id = p.trackIDs[i]
+		}
+		if err := body.WriteByte(id); err != nil {
+			return err
+		}
+
+		name := p.instrumentNames[i]
+		if err := binary.Write(&body, binary.BigEndian, int32(len(name))); err != nil {
+			return err
+		}
+		if _, err := body.WriteString(name); err != nil {
+			return err
+		}
+
+		steps := make([]byte, spliceSteps)
+		for j, on := range row {
+			if on {
+				steps[j] = 0x01
+			}
+		}
+		if _, err := body.Write(steps); err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.Write(spliceMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, int64(body.Len())); err != nil {
+		return err
+	}
+	_, err := w.Write(body.Bytes())
+	return err
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// buildSplice assembles a minimal but well-formed .splice file byte-for-byte,
+// mirroring the fixtures used in the standard Go Challenge #1 dataset
+// (e.g. pattern_1.splice: HW Version "0.808-alpha", tempo 120, one track per
+// instrument with 16 steps of 'x'/'-').
+func buildSplice(t *testing.T, version string, tempo float32, tracks []struct {
+	id    byte
+	name  string
+	steps string
+}) []byte {
+	t.Helper()
+
+	var body bytes.Buffer
+	versionBytes := make([]byte, 32)
+	copy(versionBytes, version)
+	body.Write(versionBytes)
+	binary.Write(&body, binary.LittleEndian, tempo)
+
+	for _, tr := range tracks {
+		body.WriteByte(tr.id)
+		binary.Write(&body, binary.BigEndian, int32(len(tr.name)))
+		body.WriteString(tr.name)
+		for _, c := range tr.steps {
+			if c == 'x' {
+				body.WriteByte(0x01)
+			} else {
+				body.WriteByte(0x00)
+			}
+		}
+	}
+
+	var file bytes.Buffer
+	file.Write(spliceMagic)
+	binary.Write(&file, binary.BigEndian, int64(body.Len()))
+	file.Write(body.Bytes())
+	return file.Bytes()
+}
+
+func TestDecodeSpliceFixture(t *testing.T) {
+	raw := buildSplice(t, "0.808-alpha", 120, []struct {
+		id    byte
+		name  string
+		steps string
+	}{
+		{0, "kick", "x---x---x---x---"},
+		{1, "snare", "----x-------x---"},
+		{2, "clap", "----x-x---------"},
+	})
+
+	p, err := DecodeSplice(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("DecodeSplice: %v", err)
+	}
+
+	if p.version != "0.808-alpha" {
+		t.Errorf("version = %q, want %q", p.version, "0.808-alpha")
+	}
+	if p.tempo != 120 {
+		t.Errorf("tempo = %v, want 120", p.tempo)
+	}
+	if len(p.instrumentNames) != 3 {
+		t.Fatalf("got %d tracks, want 3", len(p.instrumentNames))
+	}
+	if p.instrumentNames[0] != "kick" || p.instrumentNames[1] != "snare" || p.instrumentNames[2] != "clap" {
+		t.Errorf("instrumentNames = %v", p.instrumentNames)
+	}
+	if !p.track[0][0] || p.track[0][1] {
+		t.Errorf("kick steps = %v, want x--- to start", p.track[0])
+	}
+}
+
+func TestSpliceRoundTrip(t *testing.T) {
+	raw := buildSplice(t, "0.909", 98.4, []struct {
+		id    byte
+		name  string
+		steps string
+	}{
+		{0, "hi-hat", "x-x-x-x-x-x-x-x-"},
+		{1, "cowbell", "----------x-----"},
+	})
+
+	p, err := DecodeSplice(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("DecodeSplice: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := EncodeSplice(p, &out); err != nil {
+		t.Fatalf("EncodeSplice: %v", err)
+	}
+
+	if !bytes.Equal(raw, out.Bytes()) {
+		t.Fatalf("round trip not byte-identical:\n got  %x\n want %x", out.Bytes(), raw)
+	}
+}
+
+func TestDecodeSpliceBadMagic(t *testing.T) {
+	_, err := DecodeSplice(bytes.NewReader([]byte("NOTSPLICE")))
+	if err == nil {
+		t.Fatal("expected error for bad magic, got nil")
+	}
+}
+
+func TestDecodeSpliceInvalidNameLength(t *testing.T) {
+	var body bytes.Buffer
+	body.Write(make([]byte, 32))                           // version
+	binary.Write(&body, binary.LittleEndian, float32(120)) // tempo
+	body.WriteByte(0)                                      // track id
+	binary.Write(&body, binary.BigEndian, int32(-1))       // corrupt, negative name length
+
+	var file bytes.Buffer
+	file.Write(spliceMagic)
+	binary.Write(&file, binary.BigEndian, int64(body.Len()))
+	file.Write(body.Bytes())
+
+	if _, err := DecodeSplice(bytes.NewReader(file.Bytes())); err == nil {
+		t.Fatal("expected error for negative name length, got nil")
+	}
+}